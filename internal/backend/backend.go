@@ -0,0 +1,66 @@
+// Package backend abstracts filesystem access behind a small interface so
+// the scanner, file table, and deletion pipeline can operate over local
+// disks or remote targets (e.g. SFTP) without special-casing either one.
+package backend
+
+import "time"
+
+// Entry describes a single directory entry, backend-agnostically.
+//
+// Owner, Group, and Links are optional: a backend that can't provide one
+// leaves it zero-valued rather than inventing a meaningless value
+// (SFTPBackend has no owner/group names, so it fills Owner/Group with the
+// numeric UID/GID as decimal strings, and no link count, so Links stays
+// zero), so callers should render an empty Owner/Group or a zero Links as
+// a blank column instead of "0" or "root".
+type Entry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+	Mode    string // ls -al style permission string, e.g. "-rw-r--r--"
+
+	Owner string
+	Group string
+	Links uint64
+}
+
+// Backend abstracts read/stat/remove access to a tree of files or
+// directories, plus joining path segments the way the backend's namespace
+// expects (LocalBackend defers to path/filepath; SFTPBackend to path, since
+// remote paths are always "/"-separated regardless of the client OS).
+type Backend interface {
+	// ReadDir lists the entries directly inside path.
+	ReadDir(path string) ([]Entry, error)
+	// Stat returns the Entry describing path itself; a symlink is
+	// described as the link, not followed.
+	Stat(path string) (Entry, error)
+	// Remove deletes path (must be empty, if it's a directory).
+	Remove(path string) error
+	// Join joins path elements using the backend's separator conventions.
+	Join(elem ...string) string
+}
+
+// RemoveAll recursively removes path via be: every entry inside it first
+// (depth-first), then path itself. Backends only need to implement a
+// single-entry Remove; this generic walk is what gives every backend
+// (including ones like SFTP with no native recursive-remove call)
+// recursive directory delete.
+func RemoveAll(be Backend, path string) error {
+	entry, err := be.Stat(path)
+	if err != nil {
+		return err
+	}
+	if entry.IsDir {
+		children, err := be.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := RemoveAll(be, be.Join(path, child.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return be.Remove(path)
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// LocalBackend implements Backend over the local filesystem via os,
+// path/filepath, and syscall — the access disk_usage always had, before
+// Backend existed to abstract it.
+type LocalBackend struct{}
+
+// NewLocal returns a Backend over the local filesystem.
+func NewLocal() *LocalBackend {
+	return &LocalBackend{}
+}
+
+func (*LocalBackend) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (*LocalBackend) ReadDir(path string) ([]Entry, error) {
+	des, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(des))
+	for _, de := range des {
+		info, err := de.Info()
+		if err != nil {
+			// The entry disappeared (or became unreadable) between the
+			// ReadDir and the Info() call; skip it rather than fail the
+			// whole listing.
+			continue
+		}
+		entries = append(entries, entryFromInfo(info))
+	}
+	return entries, nil
+}
+
+func (*LocalBackend) Stat(path string) (Entry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entryFromInfo(info), nil
+}
+
+func (*LocalBackend) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// entryFromInfo fills in Owner/Group/Links from the os-specific
+// syscall.Stat_t when available — this is the only place in the codebase
+// that should still reach into syscall.Stat_t directly.
+func entryFromInfo(info os.FileInfo) Entry {
+	e := Entry{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+		Mode:    info.Mode().String(),
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		e.Links = uint64(stat.Nlink)
+		e.Owner = strconv.Itoa(int(stat.Uid))
+		e.Group = strconv.Itoa(int(stat.Gid))
+		if u, err := user.LookupId(e.Owner); err == nil {
+			e.Owner = u.Username
+		}
+		if g, err := user.LookupGroupId(e.Group); err == nil {
+			e.Group = g.Name
+		}
+	}
+	return e
+}
@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalBackendReadDirAndStat(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	be := NewLocal()
+	entries, err := be.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	info, err := be.Stat(be.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.IsDir || info.Size != 2 {
+		t.Fatalf("Stat(a.txt) = %+v, want a 2-byte file", info)
+	}
+}
+
+func TestRemoveAllRecurses(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "sub", "nested")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "f.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	be := NewLocal()
+	if err := RemoveAll(be, filepath.Join(dir, "sub")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Fatalf("expected sub to be gone, got err=%v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKnownHostsCallbackFailsClosedWithoutAKnownHostsFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := knownHostsCallback(); err == nil {
+		t.Fatal("expected an error with no ~/.ssh/known_hosts, got nil")
+	}
+}
+
+func TestKnownHostsCallbackLoadsAnExistingFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	known := "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"
+	if err := os.WriteFile(filepath.Join(sshDir, "known_hosts"), []byte(known), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := knownHostsCallback(); err != nil {
+		t.Fatalf("knownHostsCallback: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package config loads and saves the user's persisted preferences (column
+// attributes, sort key, directory interleaving) to a YAML file under
+// $XDG_CONFIG_HOME, so choices made with the "a"/"s"/"S" keybindings survive
+// across runs.
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AttrMode selects how much per-file detail the file table shows.
+type AttrMode string
+
+const (
+	// AttrFull shows the full ls -al style columns.
+	AttrFull AttrMode = "full"
+	// AttrSizeName shows only the size and name columns.
+	AttrSizeName AttrMode = "size-name"
+	// AttrNameOnly shows only the name column.
+	AttrNameOnly AttrMode = "name-only"
+)
+
+// attrCycle is the order the "a" keybinding cycles through.
+var attrCycle = []AttrMode{AttrFull, AttrSizeName, AttrNameOnly}
+
+// Next returns the attribute mode that follows m in the cycle, wrapping
+// around and falling back to AttrFull for an unrecognized value.
+func (m AttrMode) Next() AttrMode {
+	for i, mode := range attrCycle {
+		if mode == m {
+			return attrCycle[(i+1)%len(attrCycle)]
+		}
+	}
+	return AttrFull
+}
+
+// SortKey selects how the file table orders its rows.
+type SortKey string
+
+const (
+	SortSizeDesc SortKey = "size-desc"
+	SortSizeAsc  SortKey = "size-asc"
+	SortName     SortKey = "name"
+	SortMTime    SortKey = "mtime"
+	SortExt      SortKey = "extension"
+)
+
+// sortCycle is the order the "s" keybinding cycles through.
+var sortCycle = []SortKey{SortSizeDesc, SortSizeAsc, SortName, SortMTime, SortExt}
+
+// Next returns the sort key that follows k in the cycle, wrapping around
+// and falling back to SortSizeDesc for an unrecognized value.
+func (k SortKey) Next() SortKey {
+	for i, key := range sortCycle {
+		if key == k {
+			return sortCycle[(i+1)%len(sortCycle)]
+		}
+	}
+	return SortSizeDesc
+}
+
+// Config holds the user's persisted TUI preferences.
+type Config struct {
+	AttrMode    AttrMode `yaml:"attr_mode"`
+	SortKey     SortKey  `yaml:"sort_key"`
+	ShowSubdirs bool     `yaml:"show_subdirs"`
+}
+
+// Default returns the Config used when no config file exists yet.
+func Default() *Config {
+	return &Config{
+		AttrMode:    AttrFull,
+		SortKey:     SortSizeDesc,
+		ShowSubdirs: false,
+	}
+}
+
+// path returns the config file path, honoring $XDG_CONFIG_HOME and falling
+// back to ~/.config.
+func path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "disk_usage", "config.yaml"), nil
+}
+
+// Load reads the config file, returning Default() if it does not exist yet.
+func Load() (*Config, error) {
+	p, err := path()
+	if err != nil {
+		return Default(), err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return Default(), err
+	}
+	cfg := Default()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return Default(), err
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to the config file, creating its parent directory if
+// needed.
+func (cfg *Config) Save() error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestAttrModeNextCyclesAndWraps(t *testing.T) {
+	m := AttrFull
+	for _, want := range []AttrMode{AttrSizeName, AttrNameOnly, AttrFull} {
+		m = m.Next()
+		if m != want {
+			t.Fatalf("got %q, want %q", m, want)
+		}
+	}
+}
+
+func TestSortKeyNextCyclesAndWraps(t *testing.T) {
+	k := SortSizeDesc
+	for _, want := range []SortKey{SortSizeAsc, SortName, SortMTime, SortExt, SortSizeDesc} {
+		k = k.Next()
+		if k != want {
+			t.Fatalf("got %q, want %q", k, want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Package scan implements an incremental, concurrent filesystem walker that
+// streams progress back to callers as ScanEvents, so a caller (typically a
+// UI) can render results as they arrive instead of blocking until the whole
+// tree has been built.
+package scan
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"disk_usage/internal/backend"
+)
+
+// DirTree holds information about a directory, including its aggregated size,
+// its subdirectories, and the files directly in it.
+//
+// This is the same shape the original synchronous walker produced; it lives
+// here now because the Scanner builds and mutates it concurrently as results
+// stream in.
+type DirTree struct {
+	Name    string
+	Path    string
+	Size    int64           // aggregated size in bytes (files + subdirectories)
+	SubDirs []*DirTree      // subdirectories
+	Files   []backend.Entry // files directly in this directory
+
+	mu sync.Mutex
+}
+
+// Snapshot is a race-free, point-in-time copy of a DirTree's mutable
+// fields, taken under the node's own lock. Name and Path never change
+// after a DirTree is created, so callers may keep reading those directly;
+// Size, SubDirs, and Files are written by scanDir from a background
+// goroutine for as long as a scan of that subtree is in flight, so any
+// other goroutine (the TUI included) must go through Snapshot/SizeLocked/
+// SetFiles/SetSubDirs/AppendFiles/AppendSubDirs instead of touching them
+// directly.
+type Snapshot struct {
+	Name    string
+	Path    string
+	Size    int64
+	SubDirs []*DirTree // same pointers; call Snapshot again on each for their own fields
+	Files   []backend.Entry
+}
+
+// Snapshot returns a copy of d's fields as they stood at the moment of the
+// call. Concurrent scanDir goroutines may still be appending to d (or to
+// its descendants) after this returns.
+func (d *DirTree) Snapshot() Snapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	subdirs := make([]*DirTree, len(d.SubDirs))
+	copy(subdirs, d.SubDirs)
+	files := make([]backend.Entry, len(d.Files))
+	copy(files, d.Files)
+	return Snapshot{Name: d.Name, Path: d.Path, Size: d.Size, SubDirs: subdirs, Files: files}
+}
+
+// SizeLocked returns d.Size under lock.
+func (d *DirTree) SizeLocked() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.Size
+}
+
+// AddSize adds delta to d.Size under lock and returns the new value.
+func (d *DirTree) AddSize(delta int64) int64 {
+	d.mu.Lock()
+	d.Size += delta
+	size := d.Size
+	d.mu.Unlock()
+	return size
+}
+
+// SetFiles replaces d.Files under lock.
+func (d *DirTree) SetFiles(files []backend.Entry) {
+	d.mu.Lock()
+	d.Files = files
+	d.mu.Unlock()
+}
+
+// SetSubDirs replaces d.SubDirs under lock.
+func (d *DirTree) SetSubDirs(subdirs []*DirTree) {
+	d.mu.Lock()
+	d.SubDirs = subdirs
+	d.mu.Unlock()
+}
+
+// AppendFiles appends to d.Files under lock.
+func (d *DirTree) AppendFiles(files ...backend.Entry) {
+	d.mu.Lock()
+	d.Files = append(d.Files, files...)
+	d.mu.Unlock()
+}
+
+// AppendSubDirs appends to d.SubDirs under lock.
+func (d *DirTree) AppendSubDirs(subdirs ...*DirTree) {
+	d.mu.Lock()
+	d.SubDirs = append(d.SubDirs, subdirs...)
+	d.mu.Unlock()
+}
+
+// EventType identifies the kind of ScanEvent being reported.
+type EventType int
+
+const (
+	// DirStarted is emitted right before a directory's entries are read.
+	DirStarted EventType = iota
+	// DirCompleted is emitted once a directory and all of its descendants
+	// have finished scanning; Size is the aggregated total (files plus
+	// subdirectories), while Files counts only the files directly inside it.
+	DirCompleted
+	// ErrorEvent is emitted when a path could not be read or stat'd; the
+	// scan continues past it.
+	ErrorEvent
+	// Done is emitted exactly once, after the root directory (and
+	// everything under it) has finished, or the scan was cancelled.
+	Done
+)
+
+// ScanEvent reports a single step of progress from a Scanner.
+type ScanEvent struct {
+	Type EventType
+	Path string
+	// Size is the aggregate size of Path (files plus subdirectories).
+	Size int64
+	// OwnBytes is the size of just the files directly inside Path (not
+	// counting subdirectories), so a subscriber can sum OwnBytes across
+	// every DirCompleted event to get a running total without double
+	// counting bytes already reported by child directories.
+	OwnBytes int64
+	Files    int
+	Err      error
+	// Node is the DirTree for Path. It is already linked into its parent's
+	// SubDirs by the time DirStarted is sent, so a subscriber can use it to
+	// add a placeholder UI node immediately and fill it in on DirCompleted.
+	Node *DirTree
+}
+
+// Scanner walks a directory tree with a bounded pool of workers, streaming
+// ScanEvents to a caller-supplied channel as it goes.
+type Scanner struct {
+	// Backend is the filesystem the Scanner reads from. If nil, Scan
+	// defaults to backend.NewLocal(), so existing callers that never set
+	// it keep scanning the local disk.
+	Backend backend.Backend
+	// Concurrency bounds how many directories are read at once. If <= 0,
+	// it defaults to runtime.NumCPU().
+	Concurrency int
+	// MMin, if > 0, filters out files whose modification time is older
+	// than MMin minutes.
+	MMin int
+}
+
+// NewScanner returns a Scanner configured with the given concurrency, over
+// the local filesystem. A concurrency of 0 selects a default of
+// runtime.NumCPU(); set Backend afterwards to scan a different target.
+func NewScanner(concurrency int) *Scanner {
+	return &Scanner{Concurrency: concurrency, Backend: backend.NewLocal()}
+}
+
+// Scan walks root in the background and returns a channel of ScanEvents
+// along with the (still-filling-in) root *DirTree. The channel is closed
+// after the final Done event. Cancelling ctx stops the walk early; a Done
+// event is still sent so callers can rely on the channel always closing.
+func (s *Scanner) Scan(ctx context.Context, root string) (<-chan ScanEvent, *DirTree) {
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	be := s.Backend
+	if be == nil {
+		be = backend.NewLocal()
+	}
+
+	events := make(chan ScanEvent, 64)
+	sem := make(chan struct{}, concurrency)
+
+	info, err := be.Stat(root)
+	tree := &DirTree{Path: root}
+	if err == nil {
+		tree.Name = info.Name
+	}
+
+	go func() {
+		defer close(events)
+		if err != nil {
+			events <- ScanEvent{Type: ErrorEvent, Path: root, Err: err}
+			events <- ScanEvent{Type: Done}
+			return
+		}
+		s.scanDir(ctx, be, tree, sem, events)
+		events <- ScanEvent{Type: Done}
+	}()
+
+	return events, tree
+}
+
+// scanDir reads dir.Path via be, recursing into each subdirectory on its own
+// goroutine (sem bounds how many ReadDir calls run at once) and filtering
+// files by MMin, then emits a DirCompleted event for dir once all of its
+// descendants have finished.
+func (s *Scanner) scanDir(ctx context.Context, be backend.Backend, dir *DirTree, sem chan struct{}, events chan<- ScanEvent) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	events <- ScanEvent{Type: DirStarted, Path: dir.Path, Node: dir}
+
+	sem <- struct{}{}
+	entries, err := be.ReadDir(dir.Path)
+	<-sem
+	if err != nil {
+		events <- ScanEvent{Type: ErrorEvent, Path: dir.Path, Err: err, Node: dir}
+		events <- ScanEvent{Type: DirCompleted, Path: dir.Path, Node: dir}
+		return
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		fullPath := be.Join(dir.Path, entry.Name)
+
+		if entry.IsDir {
+			child := &DirTree{Name: entry.Name, Path: fullPath}
+			dir.mu.Lock()
+			dir.SubDirs = append(dir.SubDirs, child)
+			dir.mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.scanDir(ctx, be, child, sem, events)
+			}()
+			continue
+		}
+
+		if s.MMin > 0 {
+			minutesOld := now.Sub(entry.ModTime).Minutes()
+			if minutesOld >= float64(s.MMin) {
+				continue
+			}
+		}
+		dir.mu.Lock()
+		dir.Files = append(dir.Files, entry)
+		dir.mu.Unlock()
+	}
+
+	wg.Wait()
+
+	dir.mu.Lock()
+	var ownBytes int64
+	for _, f := range dir.Files {
+		ownBytes += f.Size
+	}
+	dir.Size = ownBytes
+	for _, sub := range dir.SubDirs {
+		dir.Size += sub.Size
+	}
+	size, files := dir.Size, len(dir.Files)
+	dir.mu.Unlock()
+
+	events <- ScanEvent{Type: DirCompleted, Path: dir.Path, Size: size, OwnBytes: ownBytes, Files: files, Node: dir}
+}
@@ -0,0 +1,140 @@
+// Package snapshot serializes a scan.DirTree to disk (as a single JSON
+// document or as NDJSON, one record per directory) and loads it back, so a
+// scan can be saved, diffed against later, or reopened without rescanning.
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"disk_usage/internal/backend"
+	"disk_usage/internal/scan"
+)
+
+// document is the --export format when --ndjson is not set: the capture
+// time plus the full tree, nested exactly as scanned.
+type document struct {
+	CapturedAt time.Time     `json:"captured_at"`
+	Tree       *scan.DirTree `json:"tree"`
+}
+
+// record is one directory's worth of the --ndjson format. ParentPath links
+// it back to its parent (empty for the root), since NDJSON has no way to
+// nest directories the way a single JSON document can; CapturedAt is only
+// populated on the root record to avoid repeating it on every line.
+type record struct {
+	CapturedAt time.Time       `json:"captured_at,omitempty"`
+	ParentPath string          `json:"parent_path,omitempty"`
+	Path       string          `json:"path"`
+	Name       string          `json:"name"`
+	Size       int64           `json:"size"`
+	Files      []backend.Entry `json:"files,omitempty"`
+}
+
+// Export writes tree to w: a single indented JSON object if ndjson is
+// false, or one JSON record per directory (parent before children, so Load
+// can link them back up on the way in) if it's true.
+func Export(w io.Writer, tree *scan.DirTree, capturedAt time.Time, ndjson bool) error {
+	if !ndjson {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(document{CapturedAt: capturedAt, Tree: tree})
+	}
+
+	enc := json.NewEncoder(w)
+	var walk func(dt *scan.DirTree, parentPath string) error
+	walk = func(dt *scan.DirTree, parentPath string) error {
+		rec := record{
+			ParentPath: parentPath,
+			Path:       dt.Path,
+			Name:       dt.Name,
+			Size:       dt.Size,
+			Files:      dt.Files,
+		}
+		if parentPath == "" {
+			rec.CapturedAt = capturedAt
+		}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+		for _, sub := range dt.SubDirs {
+			if err := walk(sub, dt.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(tree, "")
+}
+
+// Load reads back a tree written by Export. ndjson must match the flag
+// Export was called with, since the two formats aren't self-describing.
+func Load(r io.Reader, ndjson bool) (*scan.DirTree, time.Time, error) {
+	if !ndjson {
+		var doc document
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, time.Time{}, err
+		}
+		return doc.Tree, doc.CapturedAt, nil
+	}
+
+	byPath := make(map[string]*scan.DirTree)
+	var root *scan.DirTree
+	var capturedAt time.Time
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, time.Time{}, err
+		}
+		dt := &scan.DirTree{Name: rec.Name, Path: rec.Path, Size: rec.Size, Files: rec.Files}
+		byPath[rec.Path] = dt
+		if rec.ParentPath == "" {
+			root = dt
+			capturedAt = rec.CapturedAt
+			continue
+		}
+		parent, ok := byPath[rec.ParentPath]
+		if !ok {
+			return nil, time.Time{}, fmt.Errorf("snapshot: record %q references unknown parent %q (is the file truncated?)", rec.Path, rec.ParentPath)
+		}
+		parent.SubDirs = append(parent.SubDirs, dt)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	if root == nil {
+		return nil, time.Time{}, fmt.Errorf("snapshot: no root record found")
+	}
+	return root, capturedAt, nil
+}
+
+// Sizes flattens tree into a path -> size lookup, directories keyed by
+// their own Path and files keyed by their parent directory's Path joined
+// with the file name via be. Diff mode uses this to look up a baseline
+// size for whatever's currently on screen, whether freshly scanned or
+// loaded with --import.
+func Sizes(tree *scan.DirTree, be backend.Backend) map[string]int64 {
+	sizes := make(map[string]int64)
+	var walk func(dt *scan.DirTree)
+	walk = func(dt *scan.DirTree) {
+		sizes[dt.Path] = dt.Size
+		for _, f := range dt.Files {
+			sizes[be.Join(dt.Path, f.Name)] = f.Size
+		}
+		for _, sub := range dt.SubDirs {
+			walk(sub)
+		}
+	}
+	walk(tree)
+	return sizes
+}
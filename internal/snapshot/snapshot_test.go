@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"disk_usage/internal/backend"
+	"disk_usage/internal/scan"
+)
+
+func testTree() *scan.DirTree {
+	root := &scan.DirTree{Name: "root", Path: "/root", Size: 30}
+	root.SetFiles([]backend.Entry{{Name: "a.txt", Size: 10}})
+	sub := &scan.DirTree{Name: "sub", Path: "/root/sub", Size: 20}
+	sub.SetFiles([]backend.Entry{{Name: "b.txt", Size: 20}})
+	root.SetSubDirs([]*scan.DirTree{sub})
+	return root
+}
+
+func TestExportLoadRoundTripJSON(t *testing.T) {
+	capturedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := Export(&buf, testTree(), capturedAt, false); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	got, gotCapturedAt, err := Load(&buf, false)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !gotCapturedAt.Equal(capturedAt) {
+		t.Fatalf("capturedAt = %v, want %v", gotCapturedAt, capturedAt)
+	}
+	if got.Name != "root" || got.SizeLocked() != 30 {
+		t.Fatalf("root = %+v", got)
+	}
+	if len(got.Snapshot().SubDirs) != 1 || got.Snapshot().SubDirs[0].Name != "sub" {
+		t.Fatalf("subdirs = %+v", got.Snapshot().SubDirs)
+	}
+}
+
+func TestExportLoadRoundTripNDJSON(t *testing.T) {
+	capturedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := Export(&buf, testTree(), capturedAt, true); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	got, gotCapturedAt, err := Load(&buf, true)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !gotCapturedAt.Equal(capturedAt) {
+		t.Fatalf("capturedAt = %v, want %v", gotCapturedAt, capturedAt)
+	}
+	subs := got.Snapshot().SubDirs
+	if len(subs) != 1 || subs[0].Name != "sub" || subs[0].SizeLocked() != 20 {
+		t.Fatalf("subdirs = %+v", subs)
+	}
+}
+
+func TestLoadNDJSONRejectsUnknownParent(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"path":"/root/orphan","name":"orphan","parent_path":"/root/missing"}` + "\n")
+	if _, _, err := Load(&buf, true); err == nil {
+		t.Fatal("expected an error for a record referencing an unknown parent")
+	}
+}
+
+func TestSizes(t *testing.T) {
+	sizes := Sizes(testTree(), backend.NewLocal())
+	want := map[string]int64{
+		"/root":           30,
+		"/root/a.txt":     10,
+		"/root/sub":       20,
+		"/root/sub/b.txt": 20,
+	}
+	for path, size := range want {
+		if sizes[path] != size {
+			t.Errorf("sizes[%q] = %d, want %d", path, sizes[path], size)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package trash moves deleted files and directories to a freedesktop.org
+// Trash directory instead of removing them outright, so a deletion batch
+// can be undone. See https://specifications.freedesktop.org/trash-spec/.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Trash moves paths into $XDG_DATA_HOME/Trash/files, writing a matching
+// .trashinfo file per the spec, and can later restore a batch of moves.
+type Trash struct {
+	// DryRun, when true, makes Move and Remove log what they would have
+	// done (via the returned Batch / nil error) without touching disk.
+	DryRun bool
+
+	filesDir string
+	infoDir  string
+}
+
+// New returns a Trash rooted at $XDG_DATA_HOME/Trash, falling back to
+// ~/.local/share/Trash if XDG_DATA_HOME is unset. In dry-run mode the trash
+// directories are not created.
+func New(dryRun bool) (*Trash, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	root := filepath.Join(dataHome, "Trash")
+	t := &Trash{
+		DryRun:   dryRun,
+		filesDir: filepath.Join(root, "files"),
+		infoDir:  filepath.Join(root, "info"),
+	}
+	if dryRun {
+		return t, nil
+	}
+	if err := os.MkdirAll(t.filesDir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.infoDir, 0o755); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Item records where a single trashed entry came from and where it landed,
+// so a Batch can restore it later.
+type Item struct {
+	OriginalPath string
+	TrashPath    string
+	InfoPath     string
+}
+
+// Batch is the set of items moved to trash together by one call to Move, in
+// the order they were moved; Undo restores them in reverse.
+type Batch struct {
+	Items []Item
+}
+
+// Move moves each of paths into the trash, stopping at the first error.
+// The returned Batch always reflects the items actually moved, even on
+// error, so the caller can update in-memory state to match and still
+// offer Undo for the partial batch.
+func (t *Trash) Move(paths []string) (Batch, error) {
+	var batch Batch
+	for _, p := range paths {
+		item, err := t.moveOne(p)
+		if err != nil {
+			return batch, fmt.Errorf("trash %s: %w", p, err)
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	return batch, nil
+}
+
+func (t *Trash) moveOne(path string) (Item, error) {
+	name := filepath.Base(path)
+	trashPath := uniquePath(t.filesDir, name)
+	item := Item{
+		OriginalPath: path,
+		TrashPath:    trashPath,
+		InfoPath:     filepath.Join(t.infoDir, filepath.Base(trashPath)+".trashinfo"),
+	}
+	if t.DryRun {
+		return item, nil
+	}
+
+	if err := writeTrashInfo(item.InfoPath, path); err != nil {
+		return item, err
+	}
+	if err := renameOrCopy(path, trashPath); err != nil {
+		os.Remove(item.InfoPath)
+		return item, err
+	}
+	return item, nil
+}
+
+// Undo restores every item in batch to its original location, most
+// recently trashed first, stopping at the first error.
+func (t *Trash) Undo(batch Batch) error {
+	if t.DryRun {
+		return nil
+	}
+	for i := len(batch.Items) - 1; i >= 0; i-- {
+		item := batch.Items[i]
+		if err := renameOrCopy(item.TrashPath, item.OriginalPath); err != nil {
+			return fmt.Errorf("restore %s: %w", item.OriginalPath, err)
+		}
+		os.Remove(item.InfoPath)
+	}
+	return nil
+}
+
+// Remove permanently deletes path (recursively, if it's a directory),
+// bypassing the trash entirely.
+func (t *Trash) Remove(path string) error {
+	if t.DryRun {
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// uniquePath returns a path under dir for name that doesn't already exist,
+// appending " (N)" the way most trash implementations disambiguate
+// collisions with a previously trashed file of the same name.
+func uniquePath(dir, name string) string {
+	candidate := filepath.Join(dir, name)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; pathExists(candidate); i++ {
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+	return candidate
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// writeTrashInfo writes infoPath's [Trash Info] section per the
+// freedesktop.org Trash spec: the original (absolute) Path and the
+// DeletionDate.
+func writeTrashInfo(infoPath, originalPath string) error {
+	abs, err := filepath.Abs(originalPath)
+	if err != nil {
+		abs = originalPath
+	}
+	contents := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		abs, time.Now().Format("2006-01-02T15:04:05"))
+	return os.WriteFile(infoPath, []byte(contents), 0o644)
+}
+
+// renameOrCopy moves src to dst via os.Rename, falling back to a recursive
+// copy-then-remove when src and dst are on different filesystems (EXDEV) —
+// the trash directory and the scanned tree need not share a filesystem.
+func renameOrCopy(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	} else if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree recursively copies src to dst, preserving file modes.
+func copyTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return copyFile(src, dst, info.Mode())
+	}
+	if err := os.MkdirAll(dst, info.Mode()); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
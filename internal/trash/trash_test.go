@@ -0,0 +1,66 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestTrash(t *testing.T) *Trash {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tr, err := New(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func TestMoveAndUndoRoundTrip(t *testing.T) {
+	tr := newTestTrash(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := tr.Move([]string{path})
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone after Move, got err=%v", path, err)
+	}
+
+	if err := tr.Undo(batch); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("restored file missing: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("restored content = %q, want %q", data, "hello")
+	}
+}
+
+func TestDryRunDoesNotTouchDisk(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tr, err := New(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tr.Move([]string{path}); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dry-run Move should not have touched %s: %v", path, err)
+	}
+}
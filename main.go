@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
 	tcell "github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"disk_usage/internal/backend"
+	"disk_usage/internal/config"
+	"disk_usage/internal/scan"
+	"disk_usage/internal/snapshot"
+	"disk_usage/internal/trash"
 )
 
 // Global variables to track visual selection in the file table.
@@ -21,61 +31,200 @@ var (
 )
 
 // DirTree holds information about a directory, including its aggregated size,
-// its subdirectories, and the files directly in it.
-type DirTree struct {
-	Name    string
-	Path    string
-	Size    int64         // aggregated size in bytes (files + subdirectories)
-	SubDirs []*DirTree    // subdirectories
-	Files   []os.FileInfo // files directly in this directory
+// its subdirectories, and the files directly in it. Building and updating a
+// DirTree incrementally while a scan is in flight is handled by the scan
+// package; DirTree itself lives there now.
+type DirTree = scan.DirTree
+
+// nodeIndex maps a directory's filesystem path to the tview.TreeNode that
+// currently displays it, so background scan events can find and update the
+// right node without walking the tree.
+type nodeIndex struct {
+	mu     sync.Mutex
+	byPath map[string]*tview.TreeNode
 }
 
-// buildDirTree recursively scans the directory at the given path and builds a DirTree.
-func buildDirTree(path string, mmin int) (*DirTree, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return nil, err
+func newNodeIndex() *nodeIndex {
+	return &nodeIndex{byPath: make(map[string]*tview.TreeNode)}
+}
+
+func (idx *nodeIndex) set(path string, node *tview.TreeNode) {
+	idx.mu.Lock()
+	idx.byPath[path] = node
+	idx.mu.Unlock()
+}
+
+func (idx *nodeIndex) get(path string) (*tview.TreeNode, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	node, ok := idx.byPath[path]
+	return node, ok
+}
+
+// deletionRecord captures one "d" deletion batch (see deleteRows in main)
+// so "u" can undo it: the trash batch itself, the directory it came from,
+// and the in-memory files/subdirectories to restore there.
+type deletionRecord struct {
+	batch   trash.Batch
+	dirPath string
+	files   []backend.Entry
+	subdirs []*DirTree
+	size    int64
+}
+
+// applySizeDelta adds delta to dirPath's DirTree.Size and every ancestor's
+// (found by walking filepath.Dir up through idx), refreshing each one's
+// tree node label along the way.
+func applySizeDelta(idx *nodeIndex, dirPath string, delta int64) {
+	for {
+		node, ok := idx.get(dirPath)
+		if !ok {
+			return
+		}
+		dt, ok := node.GetReference().(*DirTree)
+		if !ok {
+			return
+		}
+		size := dt.AddSize(delta)
+		node.SetText(fmt.Sprintf("%s (%s)", dt.Name, humanizeBytes(size)))
+
+		parent := filepath.Dir(dirPath)
+		if parent == dirPath {
+			return
+		}
+		dirPath = parent
 	}
+}
+
+// uiState holds the filter the user has typed with "/" and whether it is
+// currently pruning the tree view (toggled with Ctrl+F), so that a refresh
+// (r) or a background rescan can re-apply it instead of losing it.
+type uiState struct {
+	pattern    string
+	regex      *regexp.Regexp // set when pattern has a "re:" prefix and compiles
+	treePruned bool           // Ctrl+F: prune non-matching branches from the tree
+
+	matches    []*tview.TreeNode // matching tree nodes in display order, for n/N
+	matchIndex int
+}
+
+// active reports whether a filter pattern is currently set.
+func (ui *uiState) active() bool {
+	return ui.pattern != ""
+}
 
-	tree := &DirTree{
-		Name: info.Name(),
-		Path: path,
-		Size: 0,
+// setPattern compiles and stores a new filter pattern. A "re:" prefix
+// selects regexp matching; otherwise matching is a case-insensitive
+// substring search.
+func (ui *uiState) setPattern(pattern string) error {
+	ui.pattern = pattern
+	ui.regex = nil
+	if rest, ok := strings.CutPrefix(pattern, "re:"); ok && rest != "" {
+		re, err := regexp.Compile(rest)
+		if err != nil {
+			return err
+		}
+		ui.regex = re
 	}
+	return nil
+}
 
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return tree, nil
+// clear resets the filter and tree pruning entirely.
+func (ui *uiState) clear() {
+	ui.pattern = ""
+	ui.regex = nil
+	ui.treePruned = false
+	ui.matches = nil
+	ui.matchIndex = 0
+}
+
+// matchesName reports whether name matches the active filter. With no
+// filter set, everything matches.
+func (ui *uiState) matchesName(name string) bool {
+	if !ui.active() {
+		return true
+	}
+	if ui.regex != nil {
+		return ui.regex.MatchString(name)
+	}
+	return strings.Contains(strings.ToLower(name), strings.ToLower(ui.pattern))
+}
+
+// subtreeMatches reports whether dt itself, any file directly inside it, or
+// any descendant matches the active filter.
+func (ui *uiState) subtreeMatches(dt *DirTree) bool {
+	if ui.matchesName(dt.Name) {
+		return true
+	}
+	snap := dt.Snapshot()
+	for _, f := range snap.Files {
+		if ui.matchesName(f.Name) {
+			return true
+		}
+	}
+	for _, sub := range snap.SubDirs {
+		if ui.subtreeMatches(sub) {
+			return true
+		}
 	}
+	return false
+}
 
-	now := time.Now()
+// pumpScanEvents drains events from a Scanner run, updating already-visible
+// tree nodes and the footer's live progress line as results stream in. It
+// returns once events is closed (after the scan's Done event), and calls
+// onDone on the UI thread at that point. addTreeNodes is used to refresh a
+// parent node's children when one of them finishes, so newly discovered
+// subdirectories show up without the user needing to collapse/expand.
+func pumpScanEvents(app *tview.Application, events <-chan scan.ScanEvent, idx *nodeIndex, footer *tview.TextView, addTreeNodes func(*tview.TreeNode, *DirTree), onDone func()) {
+	var dirs, files int
+	var bytes int64
+	var lastErr error
 
-	for _, entry := range entries {
-		fullPath := filepath.Join(path, entry.Name())
-		if entry.IsDir() {
-			subTree, err := buildDirTree(fullPath, mmin)
-			if err == nil {
-				tree.SubDirs = append(tree.SubDirs, subTree)
-				tree.Size += subTree.Size
-			}
-		} else {
-			fileInfo, err := entry.Info()
-			if err != nil {
-				continue
-			}
-			// If mmin is greater than 0 then filter out files older than mmin minutes.
-			if mmin > 0 {
-				minutesOld := now.Sub(fileInfo.ModTime()).Minutes()
-				if minutesOld >= float64(mmin) {
-					continue
+	for ev := range events {
+		switch ev.Type {
+		case scan.DirCompleted:
+			dirs++
+			files += ev.Files
+			bytes += ev.OwnBytes
+
+			app.QueueUpdateDraw(func() {
+				if node, ok := idx.get(ev.Path); ok {
+					if dt, ok2 := node.GetReference().(*DirTree); ok2 {
+						node.SetText(fmt.Sprintf("%s (%s)", dt.Name, humanizeBytes(dt.SizeLocked())))
+					}
 				}
-			}
-			tree.Files = append(tree.Files, fileInfo)
-			tree.Size += fileInfo.Size()
+				// Refresh the parent's children so a newly completed
+				// subdirectory appears even if the parent was already expanded.
+				if parent, ok := idx.get(filepath.Dir(ev.Path)); ok {
+					if dt, ok2 := parent.GetReference().(*DirTree); ok2 {
+						parent.ClearChildren()
+						addTreeNodes(parent, dt)
+					}
+				}
+				setScanProgress(footer, dirs, files, bytes, lastErr)
+			})
+		case scan.ErrorEvent:
+			lastErr = ev.Err
+		case scan.Done:
+			app.QueueUpdateDraw(func() {
+				setScanProgress(footer, dirs, files, bytes, lastErr)
+				if onDone != nil {
+					onDone()
+				}
+			})
+			return
 		}
 	}
+}
 
-	return tree, nil
+// setScanProgress renders the footer's live scan status line.
+func setScanProgress(footer *tview.TextView, dirs, files int, bytes int64, lastErr error) {
+	if lastErr != nil {
+		footer.SetText(fmt.Sprintf(" [yellow]scanned %d dirs, %d files, %s[white] — last error: %v", dirs, files, humanizeBytes(bytes), lastErr))
+		return
+	}
+	footer.SetText(fmt.Sprintf(" scanned %d dirs, %d files, %s", dirs, files, humanizeBytes(bytes)))
 }
 
 // humanizeBytes converts a number of bytes into a human-readable string.
@@ -92,51 +241,166 @@ func humanizeBytes(s int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(s)/float64(div), "KMGTPE"[exp])
 }
 
-// fileDetails returns a slice of strings with ls -al style details for a file.
-func fileDetails(info os.FileInfo) []string {
-	// Permissions.
-	perms := info.Mode().String()
+// runExport drives scanner to completion over rootPath without starting the
+// TUI, then writes the resulting tree to outPath via snapshot.Export. It
+// discards the incremental progress events a TUI run would render live,
+// since --export only needs the finished tree.
+func runExport(scanner *scan.Scanner, rootPath, outPath string, ndjson bool) error {
+	events, tree := scanner.Scan(context.Background(), rootPath)
+	for range events {
+	}
 
-	// Try to get underlying Stat_t for extra details.
-	var links uint64 = 0
-	uid := ""
-	gid := ""
-	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
-		links = uint64(stat.Nlink)
-		uid = strconv.Itoa(int(stat.Uid))
-		gid = strconv.Itoa(int(stat.Gid))
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := snapshot.Export(f, tree, time.Now(), ndjson); err != nil {
+		return err
+	}
+	fmt.Printf("exported %s to %s\n", rootPath, outPath)
+	return nil
+}
+
+// loadSnapshotFile opens path and loads a tree written by --export; shared
+// by --import and --diff.
+func loadSnapshotFile(path string, ndjson bool) (*scan.DirTree, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer f.Close()
+	return snapshot.Load(f, ndjson)
+}
 
-		// Lookup owner name.
-		if u, err := user.LookupId(uid); err == nil {
-			uid = u.Username
+// deltaCell renders a --diff size delta for the file table's Δ column:
+// green for shrinkage, red for growth, since a smaller directory is the
+// desirable direction when hunting disk usage.
+func deltaCell(delta int64) *tview.TableCell {
+	if delta == 0 {
+		return tview.NewTableCell("=").SetAlign(tview.AlignLeft)
+	}
+	sign, color := "+", tcell.ColorRed
+	if delta < 0 {
+		sign, color, delta = "-", tcell.ColorGreen, -delta
+	}
+	return tview.NewTableCell(sign + humanizeBytes(delta)).
+		SetTextColor(color).
+		SetAlign(tview.AlignLeft)
+}
+
+// tableRow is a unified view over a file or a subdirectory, so the file
+// table can sort and optionally interleave the two (see Config.ShowSubdirs)
+// instead of only ever listing dt.Files.
+type tableRow struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+	entry   backend.Entry // zero value for directory rows
+}
+
+// tableRows builds the unified, filter-matched row set for dt, honoring
+// cfg.ShowSubdirs and sorted by cfg.SortKey.
+func tableRows(dt *DirTree, ui *uiState, cfg *config.Config, be backend.Backend) []tableRow {
+	snap := dt.Snapshot()
+	var rows []tableRow
+	for _, file := range snap.Files {
+		if !ui.matchesName(file.Name) {
+			continue
 		}
-		// Lookup group name.
-		if g, err := user.LookupGroupId(gid); err == nil {
-			gid = g.Name
+		rows = append(rows, tableRow{name: file.Name, size: file.Size, modTime: file.ModTime, entry: file})
+	}
+	if cfg.ShowSubdirs {
+		for _, sub := range snap.SubDirs {
+			if !ui.matchesName(sub.Name) {
+				continue
+			}
+			modTime := time.Time{}
+			if info, err := be.Stat(sub.Path); err == nil {
+				modTime = info.ModTime
+			}
+			rows = append(rows, tableRow{name: sub.Name, size: sub.SizeLocked(), modTime: modTime, isDir: true})
 		}
 	}
 
-	// File size.
-	size := humanizeBytes(info.Size())
-
-	// Modified time (format similar to ls).
-	modTime := info.ModTime().Format("Jan 02 15:04")
+	switch cfg.SortKey {
+	case config.SortSizeAsc:
+		sort.Slice(rows, func(i, j int) bool { return rows[i].size < rows[j].size })
+	case config.SortName:
+		sort.Slice(rows, func(i, j int) bool { return rows[i].name < rows[j].name })
+	case config.SortMTime:
+		sort.Slice(rows, func(i, j int) bool { return rows[i].modTime.After(rows[j].modTime) })
+	case config.SortExt:
+		sort.Slice(rows, func(i, j int) bool { return filepath.Ext(rows[i].name) < filepath.Ext(rows[j].name) })
+	default: // config.SortSizeDesc
+		sort.Slice(rows, func(i, j int) bool { return rows[i].size > rows[j].size })
+	}
+	return rows
+}
 
+// fileDetails returns a slice of strings with ls -al style details for a
+// row, trimmed down to fewer columns depending on cfg.AttrMode.
+func fileDetails(row tableRow, cfg *config.Config) []string {
 	// File name (append "/" if directory).
-	name := info.Name()
-	if info.IsDir() {
+	name := row.name
+	if row.isDir {
 		name += "/"
 	}
+	size := humanizeBytes(row.size)
+
+	if cfg.AttrMode == config.AttrNameOnly {
+		return []string{name}
+	}
+	if cfg.AttrMode == config.AttrSizeName {
+		return []string{size, name}
+	}
+
+	// AttrFull: ls -al style, using the backend.Entry's already-resolved
+	// stat fields (directory rows have no per-row stat beyond the
+	// aggregated size, so they stay blank).
+	perms := ""
+	links := ""
+	uid := ""
+	gid := ""
+	if !row.isDir {
+		perms = row.entry.Mode
+		if row.entry.Links > 0 {
+			links = fmt.Sprintf("%d", row.entry.Links)
+		}
+		uid = row.entry.Owner
+		gid = row.entry.Group
+	}
+	modTime := ""
+	if !row.modTime.IsZero() {
+		modTime = row.modTime.Format("Jan 02 15:04")
+	}
 
-	return []string{perms, fmt.Sprintf("%d", links), uid, gid, size, modTime, name}
+	return []string{perms, links, uid, gid, size, modTime, name}
 }
 
-// updateFileTable updates the provided tview.Table with file details for the given directory tree.
-func updateFileTable(table *tview.Table, dt *DirTree) {
+// updateFileTable updates the provided tview.Table with rows for the given
+// directory tree: files (and, if cfg.ShowSubdirs is set, subdirectories),
+// filtered by ui's active filter, sorted per cfg.SortKey, and rendered with
+// the columns selected by cfg.AttrMode. diffBaseline, when non-nil (set by
+// --diff), appends a Δ column comparing each row's size against the
+// matching path's size in a previously-exported tree.
+func updateFileTable(table *tview.Table, dt *DirTree, ui *uiState, cfg *config.Config, be backend.Backend, diffBaseline map[string]int64) {
 	table.Clear()
 
-	// Set table headers.
-	headers := []string{"Permissions", "Links", "Owner", "Group", "Size", "Modified", "Name"}
+	var headers []string
+	switch cfg.AttrMode {
+	case config.AttrNameOnly:
+		headers = []string{"Name"}
+	case config.AttrSizeName:
+		headers = []string{"Size", "Name"}
+	default:
+		headers = []string{"Permissions", "Links", "Owner", "Group", "Size", "Modified", "Name"}
+	}
+	if diffBaseline != nil {
+		headers = append(headers, "Δ")
+	}
 	for i, h := range headers {
 		cell := tview.NewTableCell("[::b]" + h).
 			SetTextColor(tcell.ColorYellow).
@@ -144,102 +408,187 @@ func updateFileTable(table *tview.Table, dt *DirTree) {
 		table.SetCell(0, i, cell)
 	}
 
-	// Sort files by size descending.
-	files := dt.Files
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Size() > files[j].Size()
-	})
-
-	// Add file rows.
-	for r, file := range files {
-		details := fileDetails(file)
-		for c, d := range details {
+	for _, row := range tableRows(dt, ui, cfg, be) {
+		r := table.GetRowCount()
+		cols := fileDetails(row, cfg)
+		for c, d := range cols {
 			cell := tview.NewTableCell(d).
 				SetAlign(tview.AlignLeft)
-			table.SetCell(r+1, c, cell)
+			table.SetCell(r, c, cell)
 		}
+		if diffBaseline != nil {
+			delta := row.size - diffBaseline[be.Join(dt.Path, row.name)]
+			table.SetCell(r, len(cols), deltaCell(delta))
+		}
+	}
+}
+
+// resetVisualSelection exits visual mode and clears its highlight on
+// table, discarding selectionStart/selectionEnd. It must be called
+// whenever something reflows table's row set; otherwise a selection range
+// recorded against the old rows (see tableRows) would resolve against
+// whatever happens to be at those indices afterward.
+func resetVisualSelection(table *tview.Table) {
+	if !visualMode {
+		return
 	}
+	visualMode = false
+	selectionStart, selectionEnd = -1, -1
+	clearVisualSelection(table)
+}
+
+// refreshFileTable rebuilds table for dt, first resetting any in-progress
+// visual selection: a new directory, a sort key/attribute/subdir-
+// interleaving toggle, or a filter change all reflow the row set, so a
+// stale selectionStart/selectionEnd index range could otherwise resolve
+// against entirely different rows than the ones highlighted on screen.
+func refreshFileTable(table *tview.Table, dt *DirTree, ui *uiState, cfg *config.Config, be backend.Backend, diffBaseline map[string]int64) {
+	resetVisualSelection(table)
+	updateFileTable(table, dt, ui, cfg, be, diffBaseline)
 }
 
 func main() {
-	// Allow passing the base directory as a command-line argument.
+	dryRun := flag.Bool("dry-run", false, "log intended delete actions without touching disk")
+	exportPath := flag.String("export", "", "scan, write the resulting tree to this file, and exit (non-interactive)")
+	ndjson := flag.Bool("ndjson", false, "use newline-delimited JSON (one record per directory) for --export/--import/--diff instead of a single JSON document")
+	importPath := flag.String("import", "", "load a previously-exported tree from this file instead of scanning")
+	diffPath := flag.String("diff", "", "annotate the file table with a Δ column comparing against a previously-exported tree")
+	flag.Parse()
+	args := flag.Args()
+
+	// Allow passing the base directory (or an "sftp://" target) as a
+	// command-line argument.
 	rootPath := "."
 	mmin := 0 // 0 means no filtering
 
-	if len(os.Args) > 1 {
-		rootPath = os.Args[1]
+	if len(args) > 0 {
+		rootPath = args[0]
+	}
+
+	// Optionally, a second argument: mmin (in minutes)
+	if len(args) > 1 {
+		if val, err := strconv.Atoi(args[1]); err == nil {
+			mmin = val
+		}
+	}
+
+	// be is the filesystem the scan and deletion pipeline run over: the
+	// local disk, or a remote host selected with an "sftp://" target.
+	var be backend.Backend
+	if strings.HasPrefix(rootPath, "sftp://") {
+		sftpBackend, remoteRoot, err := backend.DialSFTP(rootPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "connect failed:", err)
+			os.Exit(1)
+		}
+		be = sftpBackend
+		rootPath = remoteRoot
+	} else {
 		// Simple tilde expansion if the path starts with '~'
 		if rootPath[0] == '~' {
 			if usr, err := user.Current(); err == nil {
 				rootPath = filepath.Join(usr.HomeDir, rootPath[1:])
 			}
 		}
+		be = backend.NewLocal()
 	}
 
-	// Optionally, a second argument: mmin (in minutes)
-	if len(os.Args) > 2 {
-		if val, err := strconv.Atoi(os.Args[2]); err == nil {
-			mmin = val
+	// tr moves "d"-deleted files/directories to the freedesktop.org Trash
+	// (and can undo a batch via "u"); --dry-run makes it a no-op that only
+	// reports what it would have done. Freedesktop trash only makes sense
+	// for the local filesystem; on every other backend deleteRows refuses
+	// "d" and only "D" (permanent, backend.RemoveAll-based) deletes.
+	tr, err := trash.New(*dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "trash setup failed:", err)
+		os.Exit(1)
+	}
+
+	scanner := scan.NewScanner(0)
+	scanner.Backend = be
+	scanner.MMin = mmin
+
+	// --export scans non-interactively, writes the tree to disk, and exits
+	// before anything TUI-related is built.
+	if *exportPath != "" {
+		if err := runExport(scanner, rootPath, *exportPath, *ndjson); err != nil {
+			fmt.Fprintln(os.Stderr, "export failed:", err)
+			os.Exit(1)
 		}
+		return
 	}
 
-	app := tview.NewApplication()
-	scanApp := tview.NewApplication()
-
-	// Create a TextView to display the scanning progress.
-	scanTextView := tview.NewTextView()
-	scanTextView.SetBorder(true)
-	scanTextView.SetTitle("Scanning")
-	scanTextView.SetTextAlign(tview.AlignCenter)
-	scanTextView.SetText(fmt.Sprintf("Scanning folder:\n%s\nPlease wait...", rootPath))
-
-	// Set up a spinner to show progress.
-	spinnerChars := []rune{'|', '/', '-', '\\'}
-	spinnerIndex := 0
-	ticker := time.NewTicker(200 * time.Millisecond)
-	spinnerDone := make(chan struct{})
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				app.QueueUpdateDraw(func() {
-					scanTextView.SetText(fmt.Sprintf("Scanning folder:\n%s\n%s", rootPath, string(spinnerChars[spinnerIndex])))
-				})
-				spinnerIndex = (spinnerIndex + 1) % len(spinnerChars)
-			case <-spinnerDone:
-				return
-			}
+	// diffBaseline, if set, is a path -> size lookup loaded from --diff,
+	// used by updateFileTable to add a Δ column instead of scanning twice.
+	var diffBaseline map[string]int64
+	if *diffPath != "" {
+		baseline, _, err := loadSnapshotFile(*diffPath, *ndjson)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "diff failed:", err)
+			os.Exit(1)
 		}
-	}()
+		diffBaseline = snapshot.Sizes(baseline, be)
+	}
 
-	// Start scanning in a separate goroutine.
-	var mainFlex *tview.Flex
-	var rootTree *DirTree
-	var scanErr error
-	go func() {
-		rootTree, scanErr = buildDirTree(rootPath, mmin)
-		scanApp.Stop()
-		ticker.Stop()
-		close(spinnerDone)
-	}()
-
-	// Set the scanning view as the initial root.
-	if err := scanApp.SetRoot(scanTextView, true).SetFocus(scanTextView).Run(); err != nil {
-		panic(err)
+	app := tview.NewApplication()
+
+	// idx lets background scan events find the tview.TreeNode for a path
+	// so they can update its label as results stream in.
+	idx := newNodeIndex()
+
+	// ui holds the active "/" / Ctrl+F filter so it survives a rescan.
+	ui := &uiState{}
+
+	// cfg holds the persisted "a" (attributes)/"s" (sort)/"S" (show subdirs)
+	// preferences for the file table, loaded from $XDG_CONFIG_HOME.
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
 	}
 
-	if scanErr != nil {
-		app.Stop()
-		fmt.Fprintf(os.Stderr, "Error scanning directory: %v\n", scanErr)
-		os.Exit(1)
+	// footer shows live scan progress ("scanned N dirs, M files, X GB") and
+	// doubles as a status/error line once the initial scan is done.
+	footer := tview.NewTextView().SetDynamicColors(true)
+
+	// scanCancel cancels whichever scan (initial or a background rescan)
+	// is currently in flight; it is replaced each time a new scan starts.
+	var scanCancel context.CancelFunc = func() {}
+
+	// offline is true when the tree came from --import instead of a live
+	// scan: there is no Backend-backed data behind it, so "r" (rescan) is
+	// disabled and the tree root's label shows the capture time instead of
+	// "scanning...".
+	var offline bool
+	var capturedAt time.Time
+	var rootTree *DirTree
+	var events <-chan scan.ScanEvent
+
+	if *importPath != "" {
+		tree, capturedAtVal, err := loadSnapshotFile(*importPath, *ndjson)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import failed:", err)
+			os.Exit(1)
+		}
+		rootTree, capturedAt, offline = tree, capturedAtVal, true
+		rootPath = rootTree.Path
+	} else {
+		ctx, cancel := context.WithCancel(context.Background())
+		scanCancel = cancel
+		events, rootTree = scanner.Scan(ctx, rootPath)
 	}
 
-	// --- Build the main UI (tree view and file table) using rootTree ---
+	// --- Build the main UI up front; it stays navigable while the scan runs. ---
 
-	// Create the tree root node.
-	treeRoot := tview.NewTreeNode(fmt.Sprintf("%s (%s)", rootTree.Name, humanizeBytes(rootTree.Size))).
+	// Create the tree root node. Its label is filled in as scan events
+	// arrive, or shows the capture time right away for an imported tree.
+	rootLabel := fmt.Sprintf("%s (scanning...)", filepath.Base(rootPath))
+	if offline {
+		rootLabel = fmt.Sprintf("%s (captured %s)", filepath.Base(rootPath), capturedAt.Format("2006-01-02 15:04"))
+	}
+	treeRoot := tview.NewTreeNode(rootLabel).
 		SetReference(rootTree).
-		SetExpanded(len(rootTree.SubDirs) > 0)
+		SetExpanded(true)
+	idx.set(rootPath, treeRoot)
 
 	// Create the left pane: a tree view.
 	treeView := tview.NewTreeView().
@@ -249,25 +598,56 @@ func main() {
 	treeView.SetBorderColor(tcell.ColorGreen)
 	treeView.SetTitle("Directories")
 
-	// Recursive function to add child nodes.
-	addTreeNodes := func(tn *tview.TreeNode, dt *DirTree) {
-		// Sort subdirectories by size (largest first).
-		sort.Slice(dt.SubDirs, func(i, j int) bool {
-			return dt.SubDirs[i].Size > dt.SubDirs[j].Size
+	var mainFlex *tview.Flex
+
+	// Recursive function to add child nodes. When ui.treePruned is set
+	// (Ctrl+F), it instead descends fully into every matching branch so
+	// the whole matched portion of the tree is visible at once, skipping
+	// branches whose subtree has no match; it also appends each matching
+	// node to ui.matches (rebuildTree empties that list first).
+	var addTreeNodes func(tn *tview.TreeNode, dt *DirTree)
+	addTreeNodes = func(tn *tview.TreeNode, dt *DirTree) {
+		// Sort a snapshot of the subdirectories by size (largest first); dt
+		// may still be getting new SubDirs appended by a background scan.
+		subs := dt.Snapshot().SubDirs
+		sort.Slice(subs, func(i, j int) bool {
+			return subs[i].SizeLocked() > subs[j].SizeLocked()
 		})
-		for _, sub := range dt.SubDirs {
-			nodeText := fmt.Sprintf("%s (%s)", sub.Name, humanizeBytes(sub.Size))
+		for _, sub := range subs {
+			if ui.treePruned && !ui.subtreeMatches(sub) {
+				continue
+			}
+			nodeText := fmt.Sprintf("%s (%s)", sub.Name, humanizeBytes(sub.SizeLocked()))
 			child := tview.NewTreeNode(nodeText).
 				SetReference(sub)
-			if len(sub.SubDirs) > 0 {
+			tn.AddChild(child)
+			idx.set(sub.Path, child)
+			if ui.treePruned {
+				child.SetExpanded(true)
+				if ui.matchesName(sub.Name) {
+					ui.matches = append(ui.matches, child)
+				}
+				addTreeNodes(child, sub)
+			} else if len(sub.Snapshot().SubDirs) > 0 {
 				child.SetExpanded(true)
 			}
-			tn.AddChild(child)
 		}
 	}
-	// Prepopulate the first level.
+	// Prepopulate the first level (may still be empty; it fills in as the
+	// background scan reports results, see pumpScanEvents below).
 	addTreeNodes(treeRoot, rootTree)
 
+	// rebuildTree clears and repopulates the whole tree from rootTree,
+	// honoring the current filter/pruning state. Used when the filter
+	// pattern changes or Ctrl+F is toggled.
+	rebuildTree := func() {
+		ui.matches = nil
+		ui.matchIndex = 0
+		treeRoot.ClearChildren()
+		addTreeNodes(treeRoot, rootTree)
+		treeRoot.SetExpanded(true)
+	}
+
 	// Create the right pane: a table to show file details.
 	fileTable := tview.NewTable()
 	fileTable.SetFixed(1, 0)
@@ -276,6 +656,139 @@ func main() {
 	fileTable.SetTitle("Files")
 	fileTable.SetSelectable(true, false)
 
+	// lastDeletion is the most recent non-permanent "d" deletion batch, so
+	// "u" can restore it; it is replaced (not stacked) by the next deletion
+	// and cleared once undone.
+	var lastDeletion *deletionRecord
+
+	// isLocal reports whether be is the local filesystem; freedesktop.org
+	// trash is a local-disk concept, so soft deletes ("d") only make sense
+	// there, while permanent deletes ("D") work over any Backend.
+	_, isLocal := be.(*backend.LocalBackend)
+
+	// deleteRows moves (or, if permanent, permanently removes) rows out of
+	// dt, updates dt's and its ancestors' aggregated sizes in-memory, and
+	// refreshes dt's tree node children and the file table to match. It
+	// reports the outcome on footer instead of silently ignoring failures,
+	// and returns a deletionRecord "u" can pass to undoDeletion (nil for a
+	// permanent delete, a dry run, one that removed nothing, or a trash
+	// request against a non-local backend).
+	deleteRows := func(dt *DirTree, rows []tableRow, permanent bool) *deletionRecord {
+		if !permanent && !isLocal {
+			footer.SetText(" [red]trash (d) only works on the local filesystem; use D to permanently delete[white]")
+			return nil
+		}
+		if tr.DryRun {
+			var total int64
+			for _, row := range rows {
+				total += row.size
+			}
+			verb := "would trash"
+			if permanent {
+				verb = "would permanently delete"
+			}
+			footer.SetText(fmt.Sprintf(" [yellow]dry-run: %s %d item(s), %s[white]", verb, len(rows), humanizeBytes(total)))
+			return nil
+		}
+
+		var removed []tableRow
+		var batch trash.Batch
+		var lastErr error
+		if permanent {
+			for _, row := range rows {
+				if err := backend.RemoveAll(be, be.Join(dt.Path, row.name)); err != nil {
+					lastErr = err
+					break
+				}
+				removed = append(removed, row)
+			}
+		} else {
+			paths := make([]string, len(rows))
+			for i, row := range rows {
+				paths[i] = be.Join(dt.Path, row.name)
+			}
+			batch, lastErr = tr.Move(paths)
+			removed = rows[:len(batch.Items)]
+		}
+
+		removedNames := make(map[string]bool, len(removed))
+		for _, row := range removed {
+			removedNames[row.name] = true
+		}
+
+		record := &deletionRecord{dirPath: dt.Path, batch: batch}
+		snap := dt.Snapshot()
+		var newFiles []backend.Entry
+		for _, f := range snap.Files {
+			if removedNames[f.Name] {
+				record.files = append(record.files, f)
+				record.size += f.Size
+				continue
+			}
+			newFiles = append(newFiles, f)
+		}
+		dt.SetFiles(newFiles)
+
+		var newSubDirs []*DirTree
+		for _, sub := range snap.SubDirs {
+			if removedNames[sub.Name] {
+				record.subdirs = append(record.subdirs, sub)
+				record.size += sub.SizeLocked()
+				continue
+			}
+			newSubDirs = append(newSubDirs, sub)
+		}
+		dt.SetSubDirs(newSubDirs)
+
+		applySizeDelta(idx, dt.Path, -record.size)
+		if node, ok := idx.get(dt.Path); ok {
+			node.ClearChildren()
+			addTreeNodes(node, dt)
+		}
+		refreshFileTable(fileTable, dt, ui, cfg, be, diffBaseline)
+
+		switch {
+		case lastErr != nil:
+			footer.SetText(fmt.Sprintf(" [red]delete failed after %d item(s): %v[white]", len(removed), lastErr))
+		case permanent:
+			footer.SetText(fmt.Sprintf(" permanently deleted %d item(s), freed %s", len(removed), humanizeBytes(record.size)))
+		default:
+			footer.SetText(fmt.Sprintf(" trashed %d item(s), freed %s (u to undo)", len(removed), humanizeBytes(record.size)))
+		}
+
+		if permanent || len(removed) == 0 {
+			return nil
+		}
+		return record
+	}
+
+	// undoDeletion restores record's files and subdirectories into the
+	// DirTree they were removed from (found via idx), reverses the size
+	// adjustment up the ancestor chain, and refreshes that node's children
+	// and the file table.
+	undoDeletion := func(record *deletionRecord) {
+		if err := tr.Undo(record.batch); err != nil {
+			footer.SetText(fmt.Sprintf(" [red]undo failed: %v[white]", err))
+			return
+		}
+		node, ok := idx.get(record.dirPath)
+		if !ok {
+			footer.SetText(" [red]undo failed: directory is no longer open[white]")
+			return
+		}
+		dt, ok := node.GetReference().(*DirTree)
+		if !ok {
+			return
+		}
+		dt.AppendFiles(record.files...)
+		dt.AppendSubDirs(record.subdirs...)
+		applySizeDelta(idx, record.dirPath, record.size)
+		node.ClearChildren()
+		addTreeNodes(node, dt)
+		refreshFileTable(fileTable, dt, ui, cfg, be, diffBaseline)
+		footer.SetText(fmt.Sprintf(" restored %d item(s)", len(record.files)+len(record.subdirs)))
+	}
+
 	fileTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Ensure we have the current directory from the tree view.
 		node := treeView.GetCurrentNode()
@@ -332,8 +845,12 @@ func main() {
 					}
 				}
 				return nil
-			case 'd':
-				// Handle deletion.
+			case 'd', 'D':
+				// 'd' moves the selection to trash (undo with "u"); 'D'
+				// (shift-d) permanently deletes it after an extra
+				// confirmation. Either way, selection spans a visual-mode
+				// range or just the current row.
+				permanent := event.Rune() == 'D'
 				var selectedRows []int
 				if visualMode {
 					// Use the range between selectionStart and selectionEnd.
@@ -349,58 +866,87 @@ func main() {
 					row, _ := fileTable.GetSelection()
 					selectedRows = []int{row}
 				}
-				// Build the list of file names.
-				var filesToDelete []string
+				// Resolve rows using the same order updateFileTable
+				// rendered, which may include interleaved subdirectories
+				// and may differ from dt.Files' own order once
+				// sorting/filtering is in play.
+				allRows := tableRows(dt, ui, cfg, be)
+				var toDelete []tableRow
 				for _, r := range selectedRows {
-					if r > 0 && r-1 < len(dt.Files) { // row 0 is header.
-						fileInfo := dt.Files[r-1]
-						filesToDelete = append(filesToDelete, fileInfo.Name())
+					if r > 0 && r-1 < len(allRows) { // row 0 is header.
+						toDelete = append(toDelete, allRows[r-1])
 					}
 				}
-				if len(filesToDelete) == 0 {
+				if len(toDelete) == 0 {
 					return event
 				}
-				// Show the deletion confirmation modal.
-				showMultiDeleteModal(app, dt.Path, filesToDelete, func(deleted bool) {
-					if deleted {
-						// Remove deleted files from dt.Files.
-						var newFiles []os.FileInfo
-						for _, fileInfo := range dt.Files {
-							keep := true
-							for _, name := range filesToDelete {
-								if fileInfo.Name() == name {
-									keep = false
-									break
-								}
-							}
-							if keep {
-								newFiles = append(newFiles, fileInfo)
-							}
-						}
-						dt.Files = newFiles
-						updateFileTable(fileTable, dt)
-					} else {
-						clearVisualSelection(fileTable)
-					}
-					// Reset selection state.
+				finish := func() {
 					visualMode = false
 					selectionStart = -1
 					selectionEnd = -1
 					app.SetRoot(mainFlex, true)
 					app.SetFocus(fileTable)
-				})
+				}
+				confirm := func(confirmed bool) {
+					if confirmed {
+						record := deleteRows(dt, toDelete, permanent)
+						if !permanent {
+							lastDeletion = record
+						}
+					} else {
+						clearVisualSelection(fileTable)
+					}
+					finish()
+				}
+				if permanent {
+					// Extra confirmation: only after the first "Yes" do we
+					// show the harsher, no-undo warning.
+					showMultiDeleteModal(app, dt, toDelete, false, func(confirmed bool) {
+						if !confirmed {
+							confirm(false)
+							return
+						}
+						showMultiDeleteModal(app, dt, toDelete, true, confirm)
+					})
+				} else {
+					showMultiDeleteModal(app, dt, toDelete, false, confirm)
+				}
+				return nil
+			case 'u':
+				// Undo the most recent trash ("d") deletion batch.
+				if lastDeletion == nil {
+					return nil
+				}
+				undoDeletion(lastDeletion)
+				lastDeletion = nil
 				return nil
 			}
 		}
 		return event
 	})
 
-	updateFileTable(fileTable, rootTree)
+	refreshFileTable(fileTable, rootTree, ui, cfg, be, diffBaseline)
+
+	// An imported tree has no live scan behind it: events is nil and
+	// there's nothing to drain.
+	if !offline {
+		// Drain scan events in the background, updating the tree, footer
+		// and file table as results stream in, without blocking the UI
+		// thread.
+		go pumpScanEvents(app, events, idx, footer, addTreeNodes, func() {
+			treeRoot.SetExpanded(len(rootTree.Snapshot().SubDirs) > 0)
+			if node := treeView.GetCurrentNode(); node != nil {
+				if dt, ok := node.GetReference().(*DirTree); ok {
+					refreshFileTable(fileTable, dt, ui, cfg, be, diffBaseline)
+				}
+			}
+		})
+	}
 
 	// When the selection changes in the tree, update the file table.
 	treeView.SetChangedFunc(func(node *tview.TreeNode) {
 		if dt, ok := node.GetReference().(*DirTree); ok {
-			updateFileTable(fileTable, dt)
+			refreshFileTable(fileTable, dt, ui, cfg, be, diffBaseline)
 		}
 	})
 	// Toggle expansion when a node is selected.
@@ -427,42 +973,120 @@ func main() {
 				treeView.SetBorderColor(tview.Styles.BorderColor)
 				return nil
 			case 'r':
-				// Refresh the selected directory node.
+				// Schedule a background rescan of the selected subtree; the
+				// UI stays responsive and the node/footer update as results
+				// stream in via pumpScanEvents.
+				if offline {
+					footer.SetText(" [red]rescan unavailable: this tree was loaded with --import[white]")
+					return nil
+				}
 				selectedNode := treeView.GetCurrentNode()
 				dt, ok := selectedNode.GetReference().(*DirTree)
 				if !ok {
 					return event
 				}
-				// Re-scan the directory for updated disk usage.
-				newDt, err := buildDirTree(dt.Path, mmin)
-				if err != nil {
-					// (Optional) You might display an error message here.
-					return event
+				scanCancel() // stop any scan already in flight.
+				scanCtx, cancel := context.WithCancel(context.Background())
+				scanCancel = cancel
+
+				selectedNode.SetText(fmt.Sprintf("%s (rescanning...)", dt.Name))
+				rescanEvents, newDt := scanner.Scan(scanCtx, dt.Path)
+				// Swap newDt in for dt (in its parent's SubDirs, or as
+				// rootTree) so the parent refresh in pumpScanEvents and later
+				// tree rebuilds show the rescanned tree, not the old one.
+				if dt == rootTree {
+					rootTree = newDt
+				} else if parentNode, ok := idx.get(filepath.Dir(dt.Path)); ok {
+					if parentDt, ok := parentNode.GetReference().(*DirTree); ok {
+						subs := parentDt.Snapshot().SubDirs
+						for i, sub := range subs {
+							if sub == dt {
+								subs[i] = newDt
+							}
+						}
+						parentDt.SetSubDirs(subs)
+					}
 				}
-				// Update the node's reference and text with the new size.
 				selectedNode.SetReference(newDt)
-				selectedNode.SetText(fmt.Sprintf("%s (%s)", newDt.Name, humanizeBytes(newDt.Size)))
-				// If the node is expanded, clear and repopulate its children.
-				if selectedNode.IsExpanded() {
-					selectedNode.ClearChildren()
-					addTreeNodes(selectedNode, newDt)
+				idx.set(dt.Path, selectedNode)
+				selectedNode.ClearChildren()
+
+				go pumpScanEvents(app, rescanEvents, idx, footer, addTreeNodes, func() {
+					if node := treeView.GetCurrentNode(); node != nil {
+						if curDt, ok := node.GetReference().(*DirTree); ok {
+							refreshFileTable(fileTable, curDt, ui, cfg, be, diffBaseline)
+						}
+					}
+				})
+				return nil
+			case 'c':
+				// Cancel an in-progress scan or rescan.
+				scanCancel()
+				return nil
+			case 'n':
+				// Jump to the next tree-filter match.
+				if len(ui.matches) == 0 {
+					return nil
+				}
+				ui.matchIndex = (ui.matchIndex + 1) % len(ui.matches)
+				treeView.SetCurrentNode(ui.matches[ui.matchIndex])
+				return nil
+			case 'N':
+				// Jump to the previous tree-filter match.
+				if len(ui.matches) == 0 {
+					return nil
 				}
-				// Also update the file table if the refreshed node is selected.
-				updateFileTable(fileTable, newDt)
+				ui.matchIndex = (ui.matchIndex - 1 + len(ui.matches)) % len(ui.matches)
+				treeView.SetCurrentNode(ui.matches[ui.matchIndex])
 				return nil
 			}
 		}
 		return event
 	})
 
+	// filterInput is the "/" search box; it is only attached to mainFlex
+	// while the user is typing a filter.
+	filterInput := tview.NewInputField().SetLabel("/ ")
+	refreshCurrentFileTable := func() {
+		if node := treeView.GetCurrentNode(); node != nil {
+			if dt, ok := node.GetReference().(*DirTree); ok {
+				refreshFileTable(fileTable, dt, ui, cfg, be, diffBaseline)
+			}
+		}
+	}
+	filterInput.SetChangedFunc(func(text string) {
+		if err := ui.setPattern(text); err != nil {
+			footer.SetText(fmt.Sprintf(" [red]invalid filter: %v[white]", err))
+			return
+		}
+		refreshCurrentFileTable()
+	})
+	filterInput.SetDoneFunc(func(key tcell.Key) {
+		mainFlex.RemoveItem(filterInput)
+		app.SetFocus(treeView)
+		wasPruned := ui.treePruned
+		if key == tcell.KeyEscape {
+			ui.clear()
+			refreshCurrentFileTable()
+		}
+		if wasPruned {
+			rebuildTree()
+		}
+	})
+
 	thinGreenStyle := tcell.StyleDefault.
 		Foreground(tcell.ColorGreen).
 		Background(tcell.ColorBlack)
 
-	// mainFlex holds our two panes.
-	mainFlex = tview.NewFlex()
-	mainFlex.AddItem(treeView, 0, 1, true).SetBorderStyle(thinGreenStyle)
-	mainFlex.AddItem(fileTable, 0, 2, false) // right pane
+	// panes holds the tree/file-table split.
+	panes := tview.NewFlex()
+	panes.AddItem(treeView, 0, 1, true).SetBorderStyle(thinGreenStyle)
+	panes.AddItem(fileTable, 0, 2, false) // right pane
+
+	// mainFlex stacks the panes above the always-live footer status line.
+	mainFlex = tview.NewFlex().SetDirection(tview.FlexRow)
+	mainFlex.AddItem(panes, 0, 1, true)
+	mainFlex.AddItem(footer, 1, 0, false)
 
 	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
 		if visualMode {
@@ -478,8 +1102,19 @@ func main() {
 		return false
 	})
 
-	// Global key handler to capture "q" for quit and "?" for help.
+	// Global key handler to capture "q" for quit, "?" for help, and the
+	// filter subsystem ("/", Ctrl+F, Esc).
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// None of these global bindings should fire while the user is
+		// typing into the filter box: 'q'/'?' would hijack an ordinary
+		// search term containing those letters, and Escape needs to reach
+		// filterInput's own SetDoneFunc (below) so it both clears the
+		// filter *and* removes the box from mainFlex and gives up focus —
+		// duplicating just the clear here would leave the box stuck on
+		// screen, still focused, swallowing every subsequent keystroke.
+		if app.GetFocus() == filterInput {
+			return event
+		}
 		// Quit confirmation.
 		if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
 			showQuitModal(app, mainFlex)
@@ -490,6 +1125,49 @@ func main() {
 			showHelpModal(app, mainFlex)
 			return nil
 		}
+		// Open the filter input.
+		if event.Key() == tcell.KeyRune && event.Rune() == '/' {
+			filterInput.SetText(ui.pattern)
+			mainFlex.AddItem(filterInput, 1, 0, true)
+			app.SetFocus(filterInput)
+			return nil
+		}
+		// Toggle pruning the tree view down to filter matches.
+		if event.Key() == tcell.KeyCtrlF {
+			ui.treePruned = !ui.treePruned
+			rebuildTree()
+			return nil
+		}
+		// Clear the active filter.
+		if event.Key() == tcell.KeyEscape && ui.active() {
+			ui.clear()
+			rebuildTree()
+			refreshCurrentFileTable()
+			return nil
+		}
+		if event.Key() == tcell.KeyRune {
+			switch event.Rune() {
+			case 'a':
+				// Cycle the file table's attribute display.
+				cfg.AttrMode = cfg.AttrMode.Next()
+				cfg.Save()
+				refreshCurrentFileTable()
+				return nil
+			case 's':
+				// Cycle the file table's sort key.
+				cfg.SortKey = cfg.SortKey.Next()
+				cfg.Save()
+				refreshCurrentFileTable()
+				return nil
+			case 'S':
+				// Toggle whether subdirectories are interleaved into the
+				// file table alongside files.
+				cfg.ShowSubdirs = !cfg.ShowSubdirs
+				cfg.Save()
+				refreshCurrentFileTable()
+				return nil
+			}
+		}
 		return event
 	})
 
@@ -544,9 +1222,19 @@ func showHelpModal(app *tview.Application, mainFlex tview.Primitive) {
   h : Focus Directories Pane
   j : Move down the file list
   k : Move up the file list
-  d : Delete the selected file
+  d : Move the selected file(s) to trash
+  D : Permanently delete the selected file(s) (extra confirmation, no undo)
+  u : Undo the most recent trash deletion
   v : Select multiple files / disable multiple selection
-  r : Refresh the selected directory disk usage
+  r : Rescan the selected directory in the background
+  c : Cancel an in-progress (re)scan
+  / : Filter files by name (prefix with re: for regex)
+  Ctrl+F : Toggle pruning the tree to filter matches
+  n / N : Jump to next / previous tree match
+  Esc : Clear the active filter
+  a : Cycle attribute display (full / size+name / name only)
+  s : Cycle sort key (size desc / size asc / name / mtime / extension)
+  S : Toggle interleaving subdirectories into the file list
   q : Quit (with confirmation)
   ? : Show this help dialog
   
@@ -579,35 +1267,39 @@ func showHelpModal(app *tview.Application, mainFlex tview.Primitive) {
 	})
 }
 
-func showMultiDeleteModal(app *tview.Application, basePath string, fileNames []string, callback func(deleted bool)) {
-	text := fmt.Sprintf("Do you really want to delete %d files?\n", len(fileNames))
-	for _, name := range fileNames {
-		text += fmt.Sprintf(" - %s\n", name)
+// showMultiDeleteModal asks the user to confirm deleting rows from dt,
+// showing the total bytes that would be freed. permanent switches to the
+// harsher, no-undo wording used for the "D" keybinding's second
+// confirmation; it never touches the filesystem itself, only calls
+// callback with the user's choice.
+func showMultiDeleteModal(app *tview.Application, dt *DirTree, rows []tableRow, permanent bool, callback func(confirmed bool)) {
+	var total int64
+	for _, row := range rows {
+		total += row.size
+	}
+	verb := "move to trash"
+	if permanent {
+		verb = "PERMANENTLY delete (this cannot be undone)"
+	}
+	text := fmt.Sprintf("Do you really want to %s %d item(s), freeing %s?\n", verb, len(rows), humanizeBytes(total))
+	for _, row := range rows {
+		name := row.name
+		if row.isDir {
+			name += "/"
+		}
+		text += fmt.Sprintf(" - %s (%s)\n", name, humanizeBytes(row.size))
 	}
 	modal := tview.NewModal()
 	modal.SetBackgroundColor(tcell.ColorBlack)
 	modal.SetText("[white]" + text).
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Yes" {
-				// Delete each file.
-				for _, name := range fileNames {
-					fullPath := filepath.Join(basePath, name)
-					os.Remove(fullPath)
-				}
-				callback(true)
-			} else {
-				callback(false)
-			}
+			callback(buttonLabel == "Yes")
 		})
 	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyRune {
 			switch event.Rune() {
 			case 'y', 'Y':
-				for _, name := range fileNames {
-					fullPath := filepath.Join(basePath, name)
-					os.Remove(fullPath)
-				}
 				callback(true)
 				return nil
 			default: